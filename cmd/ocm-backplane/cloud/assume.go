@@ -8,6 +8,8 @@ import (
 	"io"
 	"os"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/credentials"
@@ -15,12 +17,25 @@ import (
 	"github.com/openshift/backplane-cli/pkg/awsutil"
 	"github.com/openshift/backplane-cli/pkg/utils"
 	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
 )
 
 var assumeArgs struct {
-	output    string
-	debugFile string
-	console   bool
+	output          string
+	debugFile       string
+	console         bool
+	profile         string
+	credentialsFile string
+	configFile      string
+	profileRegion   string
+	profileOutput   string
+	sourceArn       string
+	sourceAccountID string
+	noSourceHeaders bool
+	clustersFile    string
+	maxConcurrent   int
+	eksClusterName  string
+	eksRegion       string
 }
 
 var StsClientWithProxy = awsutil.StsClientWithProxy
@@ -29,7 +44,7 @@ var NewStaticCredentialsProvider = credentials.NewStaticCredentialsProvider
 var AssumeRoleSequence = awsutil.AssumeRoleSequence
 
 var AssumeCmd = &cobra.Command{
-	Use:   "assume [CLUSTERID|EXTERNAL_ID|CLUSTER_NAME|CLUSTER_NAME_SEARCH]",
+	Use:   "assume [CLUSTERID|EXTERNAL_ID|CLUSTER_NAME|CLUSTER_NAME_SEARCH]...",
 	Short: "Performs the assume role chaining necessary to generate temporary access to the customer's AWS account",
 	Long: `Performs the assume role chaining necessary to generate temporary access to the customer's AWS account
 
@@ -42,6 +57,24 @@ role arn in the chain, using the previous role's credentials to assume the next
 
 By default this command will output sts credentials for the support in the given cluster account formatted as terminal envars.
 If the "--console" flag is provided, it will output a link to the web console for the target cluster's account.
+If the "--profile" flag is provided, the credentials are also upserted into a named profile in the shared AWS
+credentials/config files, so other tools that read profiles (aws --profile, terraform, kubectl exec plugins) can use them.
+
+Every assume-role call in the chain sends aws:SourceAccount/aws:SourceArn confused-deputy headers derived from the
+initial role ARN, unless overridden with the backplane config's "assume-source-arn"/"assume-source-account-id" (or
+"--source-arn"/"--source-account"), or disabled with the config's "disable-assume-source-headers" (or
+"--no-source-headers").
+
+Multiple cluster IDs (or "--clusters-file", one ID per line) assume each cluster's chain concurrently from a single
+shared JWT assumption. "--output env" only supports a single cluster; use "json"/"yaml" to get a per-cluster map of
+credentials, expiration and resolved role chain, or "--console" to print one federation URL per cluster. A single
+cluster's failure is reported without aborting the rest of the batch.
+
+"--output exec-credential" renders the assumed credentials as a client.authentication.k8s.io/v1 ExecCredential, for
+use as a kubeconfig "exec" credential plugin (e.g. "backplane cloud assume <cluster> -o exec-credential
+--eks-cluster-name my-cluster"). "--eks-cluster-name" is required: EKS IAM authentication validates the presigned
+token against the target EKS cluster's name (and "--eks-region"), not the backplane cluster ID/name/search term
+passed as the positional argument, so the two must be supplied separately.
 `,
 	Example: `With -o flag specified:
 backplane cloud assume e3b2fdc5-d9a7-435e-8870-312689cfb29c -oenv
@@ -50,16 +83,38 @@ With a debug file:
 backplane cloud assume e3b2fdc5-d9a7-435e-8870-312689cfb29c --debug-file test_arns
 
 As console url:
-backplane cloud assume e3b2fdc5-d9a7-435e-8870-312689cfb29c --console`,
-	Args: cobra.MaximumNArgs(1),
+backplane cloud assume e3b2fdc5-d9a7-435e-8870-312689cfb29c --console
+
+Into a named AWS profile:
+backplane cloud assume e3b2fdc5-d9a7-435e-8870-312689cfb29c --profile sre-prod
+
+Across a fleet of clusters:
+backplane cloud assume e3b2fdc5-d9a7-435e-8870-312689cfb29c 9a8b7c6d-1234-4abc-9def-0123456789ab -o json
+backplane cloud assume --clusters-file incident-clusters.txt -o yaml
+
+As a kubeconfig exec credential plugin:
+backplane cloud assume e3b2fdc5-d9a7-435e-8870-312689cfb29c -o exec-credential --eks-cluster-name my-cluster --eks-region us-west-2`,
+	Args: cobra.ArbitraryArgs,
 	RunE: runAssume,
 }
 
 func init() {
 	flags := AssumeCmd.Flags()
-	flags.StringVarP(&assumeArgs.output, "output", "o", "env", "Format the output of the console response. Valid values are `env`, `json`, and `yaml`.")
+	flags.StringVarP(&assumeArgs.output, "output", "o", "env", "Format the output of the console response. Valid values are `env`, `json`, `yaml`, and `exec-credential`.")
 	flags.StringVar(&assumeArgs.debugFile, "debug-file", "", "A file containing the list of ARNs to assume in order, not including the initial role ARN. Providing this flag will bypass calls to the backplane API to retrieve the assume role chain. The file should be a plain text file with each ARN on a new line.")
 	flags.BoolVar(&assumeArgs.console, "console", false, "Outputs a console url to access the targeted cluster instead of the STS credentials.")
+	flags.StringVar(&assumeArgs.profile, "profile", "", "Name of a profile to upsert the assumed credentials into the shared AWS credentials/config files, in addition to printing them.")
+	flags.StringVar(&assumeArgs.credentialsFile, "credentials-file", "", "Path to the shared AWS credentials file to write --profile into. Defaults to $AWS_SHARED_CREDENTIALS_FILE or ~/.aws/credentials.")
+	flags.StringVar(&assumeArgs.configFile, "config-file", "", "Path to the shared AWS config file to write --profile's region/output into. Defaults to $AWS_CONFIG_FILE or ~/.aws/config.")
+	flags.StringVar(&assumeArgs.profileRegion, "profile-region", "", "Region to write into --profile's config section.")
+	flags.StringVar(&assumeArgs.profileOutput, "profile-output", "json", "CLI output format to write into --profile's config section.")
+	flags.StringVar(&assumeArgs.sourceArn, "source-arn", "", "Override the aws:SourceArn confused-deputy header sent on every assume-role call in the chain. Defaults to the backplane config's `assume-source-arn`, then the initial role ARN.")
+	flags.StringVar(&assumeArgs.sourceAccountID, "source-account", "", "Override the aws:SourceAccount confused-deputy header sent on every assume-role call in the chain. Defaults to the backplane config's `assume-source-account-id`, then the account ID of the resolved aws:SourceArn.")
+	flags.BoolVar(&assumeArgs.noSourceHeaders, "no-source-headers", false, "Disable sending aws:SourceAccount/aws:SourceArn confused-deputy headers on assume-role calls. Overrides the backplane config's `disable-assume-source-headers`.")
+	flags.StringVar(&assumeArgs.clustersFile, "clusters-file", "", "A file containing cluster IDs to assume, one per line, as an alternative to passing them as positional arguments.")
+	flags.IntVar(&assumeArgs.maxConcurrent, "max-concurrent", 5, "Maximum number of cluster chains to assume concurrently when multiple clusters are given.")
+	flags.StringVar(&assumeArgs.eksClusterName, "eks-cluster-name", "", "Required with `--output exec-credential`: the target EKS cluster's name, tagged in the presigned token's x-k8s-aws-id header so aws-iam-authenticator accepts it.")
+	flags.StringVar(&assumeArgs.eksRegion, "eks-region", "us-east-1", "Region of the target EKS cluster, used to sign the `--output exec-credential` token.")
 }
 
 type assumeChainResponse struct {
@@ -72,117 +127,487 @@ type namedRoleArn struct {
 }
 
 func runAssume(_ *cobra.Command, args []string) error {
-	if len(args) == 0 && assumeArgs.debugFile == "" {
-		return fmt.Errorf("must provide either cluster ID as an argument, or --debug-file as a flag")
+	clusters, err := resolveClusterArgs(args)
+	if err != nil {
+		return err
+	}
+
+	if len(clusters) == 0 && assumeArgs.debugFile == "" {
+		return fmt.Errorf("must provide either cluster ID(s) as arguments, --clusters-file, or --debug-file as a flag")
+	}
+
+	if len(clusters) > 1 {
+		return runAssumeMulti(clusters)
+	}
+
+	var clusterArg string
+	if len(clusters) == 1 {
+		clusterArg = clusters[0]
+	}
+
+	targetCredentials, err := assumeChain(clusterArg, assumeArgs.debugFile)
+	if err != nil {
+		return err
+	}
+
+	if assumeArgs.profile != "" {
+		if err := writeAssumedProfile(targetCredentials.AccessKeyID, targetCredentials.SecretAccessKey, targetCredentials.SessionToken); err != nil {
+			return fmt.Errorf("failed to write --profile %q: %w", assumeArgs.profile, err)
+		}
+	}
+
+	if assumeArgs.console {
+		resp, err := awsutil.GetSigninToken(targetCredentials.toAWSCredentials())
+		if err != nil {
+			return fmt.Errorf("failed to get signin token from AWS: %w", err)
+		}
+
+		signInFederationURL, err := awsutil.GetConsoleURL(resp.SigninToken)
+		if err != nil {
+			return fmt.Errorf("failed to generate console url: %w", err)
+		}
+
+		fmt.Printf("The AWS Console URL is:\n%s\n", signInFederationURL.String())
+	} else if assumeArgs.output == "exec-credential" {
+		if assumeArgs.eksClusterName == "" {
+			return fmt.Errorf("--eks-cluster-name is required with --output exec-credential")
+		}
+		cred, err := newExecCredential(context.TODO(), assumeArgs.eksClusterName, assumeArgs.eksRegion, targetCredentials)
+		if err != nil {
+			return fmt.Errorf("failed to build exec credential: %w", err)
+		}
+		encoded, err := json.MarshalIndent(cred, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to format output correctly: %w", err)
+		}
+		fmt.Println(string(encoded))
+	} else {
+		credsResponse := awsutil.AWSCredentialsResponse{
+			AccessKeyID:     targetCredentials.AccessKeyID,
+			SecretAccessKey: targetCredentials.SecretAccessKey,
+			SessionToken:    targetCredentials.SessionToken,
+			Expiration:      targetCredentials.Expires.String(),
+		}
+		formattedResult, err := credsResponse.RenderOutput(assumeArgs.output)
+		if err != nil {
+			return fmt.Errorf("failed to format output correctly: %w", err)
+		}
+		fmt.Println(formattedResult)
+	}
+	return nil
+}
+
+// assumedCredentials is the trimmed-down shape of the final, target-account
+// credentials produced by walking a jump role chain, shared by every command
+// that performs the assume (assume, including its exec-credential output,
+// and credential-process).
+type assumedCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Expires         time.Time
+}
+
+func (c assumedCredentials) toAWSCredentials() awsutil.AssumeRoleResponse {
+	return awsutil.AssumeRoleResponse{
+		AccessKeyID:     c.AccessKeyID,
+		SecretAccessKey: c.SecretAccessKey,
+		SessionToken:    c.SessionToken,
+		Expires:         c.Expires,
+	}
+}
+
+// resolveClusterArgs merges the positional cluster arguments with
+// --clusters-file (mutually exclusive) into a single ordered list of cluster
+// IDs to assume.
+func resolveClusterArgs(args []string) ([]string, error) {
+	if assumeArgs.clustersFile == "" {
+		return args, nil
+	}
+	if len(args) > 0 {
+		return nil, fmt.Errorf("cannot combine positional cluster arguments with --clusters-file")
 	}
 
+	data, err := os.ReadFile(assumeArgs.clustersFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read --clusters-file: %w", err)
+	}
+
+	var clusters []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			clusters = append(clusters, line)
+		}
+	}
+	return clusters, nil
+}
+
+// clusterAssumeResult is the per-cluster outcome of a fan-out assume, keyed
+// by cluster ID in the aggregated json/yaml output.
+type clusterAssumeResult struct {
+	Credentials *awsutil.AWSCredentialsResponse `json:"credentials,omitempty" yaml:"credentials,omitempty"`
+	RoleChain   []string                        `json:"roleChain,omitempty" yaml:"roleChain,omitempty"`
+	Error       string                          `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// runAssumeMulti assumes each cluster's jump role chain concurrently, from a
+// single shared seed assumption, and reports per-cluster failures without
+// aborting the rest of the batch.
+func runAssumeMulti(clusters []string) error {
+	if assumeArgs.output == "env" && !assumeArgs.console {
+		return fmt.Errorf("--output env only supports a single cluster; use --output json/yaml, or --console, for multiple clusters")
+	}
+
+	if assumeArgs.maxConcurrent < 1 {
+		return fmt.Errorf("--max-concurrent must be at least 1, got %d", assumeArgs.maxConcurrent)
+	}
+
+	seed, err := newSeedContext()
+	if err != nil {
+		return err
+	}
+
+	type assumeOutcome struct {
+		clusterID string
+		creds     assumedCredentials
+		roleChain []string
+		err       error
+	}
+
+	outcomes := make([]assumeOutcome, len(clusters))
+	sem := make(chan struct{}, assumeArgs.maxConcurrent)
+	var wg sync.WaitGroup
+	for i, clusterID := range clusters {
+		wg.Add(1)
+		go func(i int, clusterID string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			creds, roleChain, err := seed.assumeCluster(clusterID, "")
+			outcomes[i] = assumeOutcome{clusterID: clusterID, creds: creds, roleChain: roleChain, err: err}
+		}(i, clusterID)
+	}
+	wg.Wait()
+
+	if assumeArgs.console {
+		for _, o := range outcomes {
+			if o.err != nil {
+				fmt.Printf("%s: error: %v\n", o.clusterID, o.err)
+				continue
+			}
+
+			resp, err := awsutil.GetSigninToken(o.creds.toAWSCredentials())
+			if err != nil {
+				fmt.Printf("%s: error: failed to get signin token from AWS: %v\n", o.clusterID, err)
+				continue
+			}
+
+			signInFederationURL, err := awsutil.GetConsoleURL(resp.SigninToken)
+			if err != nil {
+				fmt.Printf("%s: error: failed to generate console url: %v\n", o.clusterID, err)
+				continue
+			}
+
+			fmt.Printf("%s: %s\n", o.clusterID, signInFederationURL.String())
+		}
+		return nil
+	}
+
+	results := make(map[string]clusterAssumeResult, len(outcomes))
+	for _, o := range outcomes {
+		result := clusterAssumeResult{RoleChain: o.roleChain}
+		if o.err != nil {
+			result.Error = o.err.Error()
+		} else {
+			result.Credentials = &awsutil.AWSCredentialsResponse{
+				AccessKeyID:     o.creds.AccessKeyID,
+				SecretAccessKey: o.creds.SecretAccessKey,
+				SessionToken:    o.creds.SessionToken,
+				Expiration:      o.creds.Expires.String(),
+			}
+		}
+		results[o.clusterID] = result
+	}
+
+	formatted, err := renderClusterResults(results, assumeArgs.output)
+	if err != nil {
+		return fmt.Errorf("failed to format output correctly: %w", err)
+	}
+	fmt.Println(formatted)
+	return nil
+}
+
+// renderClusterResults formats the aggregated per-cluster results as json or
+// yaml; it mirrors awsutil.AWSCredentialsResponse.RenderOutput, which only
+// knows how to render a single cluster's credentials.
+func renderClusterResults(results map[string]clusterAssumeResult, output string) (string, error) {
+	switch output {
+	case "json":
+		encoded, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(encoded), nil
+	case "yaml":
+		encoded, err := yaml.Marshal(results)
+		if err != nil {
+			return "", err
+		}
+		return string(encoded), nil
+	default:
+		return "", fmt.Errorf("unsupported output format for multiple clusters: %q", output)
+	}
+}
+
+// seedContext holds everything obtained from the single OCM token exchange
+// and initial role assumption that every per-cluster chain in a fan-out can
+// share, so N clusters only pay for that exchange once.
+type seedContext struct {
+	ocmToken       string
+	email          string
+	proxyURL       string
+	backplaneURL   string
+	seedClient     *sts.Client
+	sourceIdentity awsutil.SourceIdentity
+}
+
+// newSeedContext performs the OCM token exchange and the initial role
+// assumption with the OCM JWT, producing the seed credentials every
+// per-cluster jump role chain assumes from.
+func newSeedContext() (seedContext, error) {
 	ocmToken, err := utils.DefaultOCMInterface.GetOCMAccessToken()
 	if err != nil {
-		return fmt.Errorf("failed to retrieve OCM token: %w", err)
+		return seedContext{}, fmt.Errorf("failed to retrieve OCM token: %w", err)
 	}
 
 	email, err := utils.GetStringFieldFromJWT(*ocmToken, "email")
 	if err != nil {
-		return fmt.Errorf("unable to extract email from given token: %w", err)
+		return seedContext{}, fmt.Errorf("unable to extract email from given token: %w", err)
 	}
 
 	bpConfig, err := GetBackplaneConfiguration()
 	if err != nil {
-		return fmt.Errorf("error retrieving backplane configuration: %w", err)
+		return seedContext{}, fmt.Errorf("error retrieving backplane configuration: %w", err)
 	}
 
 	if bpConfig.AssumeInitialArn == "" {
-		return errors.New("backplane config is missing required `assume-initial-arn` property")
+		return seedContext{}, errors.New("backplane config is missing required `assume-initial-arn` property")
 	}
 
+	sourceIdentity := resolveSourceIdentity(bpConfig)
+
 	initialClient, err := StsClientWithProxy(bpConfig.ProxyURL)
 	if err != nil {
-		return fmt.Errorf("failed to create sts client: %w", err)
+		return seedContext{}, fmt.Errorf("failed to create sts client: %w", err)
 	}
+	initialClient = sts.New(initialClient.Options(), awsutil.WithSourceIdentityHeaders(sourceIdentity))
 
 	seedCredentials, err := AssumeRoleWithJWT(*ocmToken, bpConfig.AssumeInitialArn, initialClient)
 	if err != nil {
-		return fmt.Errorf("failed to assume role using JWT: %w", err)
+		return seedContext{}, fmt.Errorf("failed to assume role using JWT: %w", err)
+	}
+
+	seedClient := sts.NewFromConfig(aws.Config{
+		Region:      "us-east-1",
+		Credentials: NewStaticCredentialsProvider(seedCredentials.AccessKeyID, seedCredentials.SecretAccessKey, seedCredentials.SessionToken),
+	}, awsutil.WithSourceIdentityHeaders(sourceIdentity))
+
+	return seedContext{
+		ocmToken:       *ocmToken,
+		email:          email,
+		proxyURL:       bpConfig.ProxyURL,
+		backplaneURL:   bpConfig.URL,
+		seedClient:     seedClient,
+		sourceIdentity: sourceIdentity,
+	}, nil
+}
+
+// assumeCluster walks the jump role chain for clusterArg (or the ARNs in
+// debugFile when set), returning the final target-account credentials and
+// the resolved chain of role names (or ARNs, in debug-file mode).
+func (s seedContext) assumeCluster(clusterArg, debugFile string) (assumedCredentials, []string, error) {
+	roleAssumeSequence, roleNames, err := s.resolveRoleSequence(clusterArg, debugFile)
+	if err != nil {
+		return assumedCredentials{}, nil, err
 	}
 
+	creds, err := s.walkRoleSequence(roleAssumeSequence)
+	if err != nil {
+		return assumedCredentials{}, nil, err
+	}
+	return creds, roleNames, nil
+}
+
+// resolveRoleSequence looks up the jump role chain for clusterArg (or reads
+// the ARNs from debugFile when set), returning the role ARNs to assume in
+// order and their corresponding display names (the ARNs themselves, in
+// debug-file mode). This is the part of assumeCluster that hits the
+// backplane API, split out so callers that refresh credentials repeatedly
+// (e.g. credentialBroker) can cache it and only re-walk the chain's STS
+// calls on each refresh.
+func (s seedContext) resolveRoleSequence(clusterArg, debugFile string) ([]string, []string, error) {
 	var roleAssumeSequence []string
-	if assumeArgs.debugFile == "" {
-		clusterID, _, err := utils.DefaultOCMInterface.GetTargetCluster(args[0])
+	var roleNames []string
+	if debugFile == "" {
+		clusterID, _, err := utils.DefaultOCMInterface.GetTargetCluster(clusterArg)
 		if err != nil {
-			return fmt.Errorf("failed to get target cluster: %w", err)
+			return nil, nil, fmt.Errorf("failed to get target cluster: %w", err)
 		}
 
-		backplaneClient, err := utils.DefaultClientUtils.MakeRawBackplaneAPIClientWithAccessToken(bpConfig.URL, *ocmToken)
+		backplaneClient, err := utils.DefaultClientUtils.MakeRawBackplaneAPIClientWithAccessToken(s.backplaneURL, s.ocmToken)
 		if err != nil {
-			return fmt.Errorf("failed to create backplane client with access token: %w", err)
+			return nil, nil, fmt.Errorf("failed to create backplane client with access token: %w", err)
 		}
 
 		response, err := backplaneClient.GetAssumeRoleSequence(context.TODO(), clusterID)
 		if err != nil {
-			return fmt.Errorf("failed to fetch arn sequence: %w", err)
+			return nil, nil, fmt.Errorf("failed to fetch arn sequence: %w", err)
 		}
 		if response.StatusCode != 200 {
-			return fmt.Errorf("failed to fetch arn sequence: %v", response.Status)
+			return nil, nil, fmt.Errorf("failed to fetch arn sequence: %v", response.Status)
 		}
 
 		bytes, err := io.ReadAll(response.Body)
 		if err != nil {
-			return fmt.Errorf("failed to read backplane API response body: %w", err)
+			return nil, nil, fmt.Errorf("failed to read backplane API response body: %w", err)
 		}
 
 		roleChainResponse := &assumeChainResponse{}
 		err = json.Unmarshal(bytes, roleChainResponse)
 		if err != nil {
-			return fmt.Errorf("failed to unmarshal response: %w", err)
+			return nil, nil, fmt.Errorf("failed to unmarshal response: %w", err)
 		}
 
 		roleAssumeSequence = make([]string, 0, len(roleChainResponse.AssumptionSequence))
+		roleNames = make([]string, 0, len(roleChainResponse.AssumptionSequence))
 		for _, namedRoleArn := range roleChainResponse.AssumptionSequence {
 			roleAssumeSequence = append(roleAssumeSequence, namedRoleArn.Arn)
+			roleNames = append(roleNames, namedRoleArn.Name)
 		}
 	} else {
-		arnBytes, err := os.ReadFile(assumeArgs.debugFile)
+		arnBytes, err := os.ReadFile(debugFile)
 		if err != nil {
-			return fmt.Errorf("failed to read file %v: %w", assumeArgs.debugFile, err)
+			return nil, nil, fmt.Errorf("failed to read file %v: %w", debugFile, err)
 		}
 
 		roleAssumeSequence = append(roleAssumeSequence, strings.Split(string(arnBytes), "\n")...)
+		roleNames = roleAssumeSequence
 	}
 
-	seedClient := sts.NewFromConfig(aws.Config{
-		Region:      "us-east-1",
-		Credentials: NewStaticCredentialsProvider(seedCredentials.AccessKeyID, seedCredentials.SecretAccessKey, seedCredentials.SessionToken),
-	})
+	return roleAssumeSequence, roleNames, nil
+}
 
-	targetCredentials, err := AssumeRoleSequence(email, seedClient, roleAssumeSequence, bpConfig.ProxyURL, awsutil.DefaultSTSClientProviderFunc)
+// walkRoleSequence assumes each role ARN in roleAssumeSequence in order,
+// starting from s.seedClient, returning the final target-account
+// credentials. Unlike resolveRoleSequence, this only makes STS calls, so
+// callers that already have a resolved sequence cached can re-walk it on
+// every credential refresh without hitting the backplane API again.
+func (s seedContext) walkRoleSequence(roleAssumeSequence []string) (assumedCredentials, error) {
+	targetCredentials, err := AssumeRoleSequence(s.email, s.seedClient, roleAssumeSequence, s.proxyURL, awsutil.DefaultSTSClientProviderFunc, s.sourceIdentity)
 	if err != nil {
-		return fmt.Errorf("failed to assume role sequence: %w", err)
+		return assumedCredentials{}, fmt.Errorf("failed to assume role sequence: %w", err)
 	}
 
-	if assumeArgs.console {
-		resp, err := awsutil.GetSigninToken(targetCredentials)
-		if err != nil {
-			return fmt.Errorf("failed to get signin token from AWS: %w", err)
-		}
+	return assumedCredentials{
+		AccessKeyID:     targetCredentials.AccessKeyID,
+		SecretAccessKey: targetCredentials.SecretAccessKey,
+		SessionToken:    targetCredentials.SessionToken,
+		Expires:         targetCredentials.Expires,
+	}, nil
+}
 
-		signInFederationURL, err := awsutil.GetConsoleURL(resp.SigninToken)
+// assumeChain is the single-cluster convenience wrapper around
+// newSeedContext + assumeCluster, used wherever only one cluster's chain is
+// assumed (single-cluster assume, including -o exec-credential, and
+// credential-process).
+func assumeChain(clusterArg, debugFile string) (assumedCredentials, error) {
+	seed, err := newSeedContext()
+	if err != nil {
+		return assumedCredentials{}, err
+	}
+	creds, _, err := seed.assumeCluster(clusterArg, debugFile)
+	return creds, err
+}
+
+// resolveSourceIdentity builds the confused-deputy header values to send on
+// every assume-role call in the chain. Precedence, highest first: the
+// --source-arn/--source-account/--no-source-headers flags, then the
+// backplane config's AssumeSourceArn/AssumeSourceAccountID/
+// DisableAssumeSourceHeaders properties, then the initial role ARN. When no
+// account ID is given, it is taken from the resolved source ARN.
+func resolveSourceIdentity(bpConfig BackplaneConfiguration) awsutil.SourceIdentity {
+	if assumeArgs.noSourceHeaders || bpConfig.DisableAssumeSourceHeaders {
+		return awsutil.SourceIdentity{}
+	}
+
+	identity := awsutil.SourceIdentity{
+		SourceArn:       assumeArgs.sourceArn,
+		SourceAccountID: assumeArgs.sourceAccountID,
+	}
+	if identity.SourceArn == "" {
+		identity.SourceArn = bpConfig.AssumeSourceArn
+	}
+	if identity.SourceArn == "" {
+		identity.SourceArn = bpConfig.AssumeInitialArn
+	}
+	if identity.SourceAccountID == "" {
+		identity.SourceAccountID = bpConfig.AssumeSourceAccountID
+	}
+	if identity.SourceAccountID == "" {
+		identity.SourceAccountID = accountIDFromArn(identity.SourceArn)
+	}
+	return identity
+}
+
+// accountIDFromArn extracts the account ID field (the 5th colon-delimited
+// segment) from an ARN such as "arn:aws:iam::123456789012:role/name".
+func accountIDFromArn(arn string) string {
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) < 5 {
+		return ""
+	}
+	return parts[4]
+}
+
+// writeAssumedProfile upserts creds into the --profile section of the shared
+// AWS credentials file, and its region/output into the matching section of
+// the shared AWS config file, leaving every other profile untouched.
+func writeAssumedProfile(accessKeyID, secretAccessKey, sessionToken string) error {
+	credentialsFile := assumeArgs.credentialsFile
+	if credentialsFile == "" {
+		path, err := awsutil.DefaultCredentialsFilePath()
 		if err != nil {
-			return fmt.Errorf("failed to generate console url: %w", err)
+			return err
 		}
+		credentialsFile = path
+	}
 
-		fmt.Printf("The AWS Console URL is:\n%s\n", signInFederationURL.String())
-	} else {
-		credsResponse := awsutil.AWSCredentialsResponse{
-			AccessKeyID:     targetCredentials.AccessKeyID,
-			SecretAccessKey: targetCredentials.SecretAccessKey,
-			SessionToken:    targetCredentials.SessionToken,
-			Expiration:      targetCredentials.Expires.String(),
-		}
-		formattedResult, err := credsResponse.RenderOutput(assumeArgs.output)
+	if err := awsutil.UpsertCredentialsProfile(credentialsFile, assumeArgs.profile, awsutil.ProfileCredentials{
+		AccessKeyID:     accessKeyID,
+		SecretAccessKey: secretAccessKey,
+		SessionToken:    sessionToken,
+	}); err != nil {
+		return fmt.Errorf("failed to update %s: %w", credentialsFile, err)
+	}
+
+	configFile := assumeArgs.configFile
+	if configFile == "" {
+		path, err := awsutil.DefaultConfigFilePath()
 		if err != nil {
-			return fmt.Errorf("failed to format output correctly: %w", err)
+			return err
 		}
-		fmt.Println(formattedResult)
+		configFile = path
+	}
+
+	if err := awsutil.UpsertConfigProfile(configFile, assumeArgs.profile, awsutil.ProfileConfig{
+		Region: assumeArgs.profileRegion,
+		Output: assumeArgs.profileOutput,
+	}); err != nil {
+		return fmt.Errorf("failed to update %s: %w", configFile, err)
 	}
+
+	fmt.Fprintf(noteOutput(), "Wrote credentials to profile %q in %s and %s\n", assumeArgs.profile, credentialsFile, configFile)
 	return nil
 }
@@ -0,0 +1,15 @@
+package cloud
+
+import "github.com/spf13/cobra"
+
+// CloudCmd is the parent "backplane cloud" command grouping the cloud
+// credential subcommands.
+var CloudCmd = &cobra.Command{
+	Use:   "cloud",
+	Short: "Cloud credential commands",
+}
+
+func init() {
+	CloudCmd.AddCommand(AssumeCmd)
+	CloudCmd.AddCommand(CredentialProcessCmd)
+}
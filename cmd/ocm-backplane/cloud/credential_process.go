@@ -0,0 +1,264 @@
+package cloud
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	mathrand "math/rand"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var credentialProcessArgs struct {
+	debugFile string
+	serve     bool
+	bindAddr  string
+	token     string
+}
+
+// CredentialProcessCmd implements the AWS SDK `credential_process` protocol
+// (https://docs.aws.amazon.com/sdkref/latest/guide/feature-process-credentials.html)
+// on top of the backplane jump role chain, so a static
+// `credential_process = backplane cloud credential-process <clusterID>` line
+// in ~/.aws/config gets transparently refreshed short-lived STS credentials.
+var CredentialProcessCmd = &cobra.Command{
+	Use:   "credential-process [CLUSTERID|EXTERNAL_ID|CLUSTER_NAME|CLUSTER_NAME_SEARCH]",
+	Short: "Serves the assumed role chain credentials in the AWS credential_process format",
+	Long: `Serves the assumed role chain credentials in the AWS credential_process format
+
+This command performs the same assume role chaining as "backplane cloud assume", but instead of printing the
+credentials once, it prints a single JSON document conforming to the AWS SDK credential_process schema:
+
+{"Version":1,"AccessKeyId":"...","SecretAccessKey":"...","SessionToken":"...","Expiration":"..."}
+
+Reference it from ~/.aws/config so the AWS SDK/CLI transparently re-invokes it and refreshes credentials before they
+expire:
+
+[profile sre-prod]
+credential_process = backplane cloud credential-process e3b2fdc5-d9a7-435e-8870-312689cfb29c
+
+With "--serve", it instead runs a loopback-only, token-protected HTTP endpoint speaking the same JSON shape used by
+AWS_CONTAINER_CREDENTIALS_FULL_URI, for tools that cannot invoke a credential_process directly.
+`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runCredentialProcess,
+}
+
+func init() {
+	flags := CredentialProcessCmd.Flags()
+	flags.StringVar(&credentialProcessArgs.debugFile, "debug-file", "", "A file containing the list of ARNs to assume in order, not including the initial role ARN. See \"backplane cloud assume --help\".")
+	flags.BoolVar(&credentialProcessArgs.serve, "serve", false, "Run a loopback HTTP endpoint serving the same rotating credentials, for AWS_CONTAINER_CREDENTIALS_FULL_URI consumers instead of credential_process.")
+	flags.StringVar(&credentialProcessArgs.bindAddr, "bind", "127.0.0.1:0", "Loopback address to bind the --serve endpoint to.")
+	flags.StringVar(&credentialProcessArgs.token, "token", "", "Bearer token required on --serve requests (Authorization header). Generated and printed to stderr if not provided.")
+}
+
+// credentialProcessResponse is the AWS SDK credential_process JSON schema.
+type credentialProcessResponse struct {
+	Version         int    `json:"Version"`
+	AccessKeyID     string `json:"AccessKeyId"`
+	SecretAccessKey string `json:"SecretAccessKey"`
+	SessionToken    string `json:"SessionToken"`
+	Expiration      string `json:"Expiration"`
+}
+
+func toCredentialProcessResponse(creds assumedCredentials) credentialProcessResponse {
+	return credentialProcessResponse{
+		Version:         1,
+		AccessKeyID:     creds.AccessKeyID,
+		SecretAccessKey: creds.SecretAccessKey,
+		SessionToken:    creds.SessionToken,
+		Expiration:      creds.Expires.Format(time.RFC3339),
+	}
+}
+
+// containerCredentialsResponse is the JSON schema ECS/EKS container
+// credential providers (and AWS_CONTAINER_CREDENTIALS_FULL_URI consumers in
+// the AWS SDKs) expect, which differs from the credential_process schema in
+// field names and omits "Version".
+type containerCredentialsResponse struct {
+	AccessKeyID     string `json:"AccessKeyId"`
+	SecretAccessKey string `json:"SecretAccessKey"`
+	Token           string `json:"Token"`
+	Expiration      string `json:"Expiration"`
+}
+
+func toContainerCredentialsResponse(creds assumedCredentials) containerCredentialsResponse {
+	return containerCredentialsResponse{
+		AccessKeyID:     creds.AccessKeyID,
+		SecretAccessKey: creds.SecretAccessKey,
+		Token:           creds.SessionToken,
+		Expiration:      creds.Expires.Format(time.RFC3339),
+	}
+}
+
+func runCredentialProcess(_ *cobra.Command, args []string) error {
+	if len(args) == 0 && credentialProcessArgs.debugFile == "" {
+		return fmt.Errorf("must provide either cluster ID as an argument, or --debug-file as a flag")
+	}
+
+	var clusterArg string
+	if len(args) > 0 {
+		clusterArg = args[0]
+	}
+
+	if credentialProcessArgs.serve {
+		return serveCredentialProcess(clusterArg)
+	}
+
+	creds, err := assumeChain(clusterArg, credentialProcessArgs.debugFile)
+	if err != nil {
+		return err
+	}
+
+	encoded, err := json.Marshal(toCredentialProcessResponse(creds))
+	if err != nil {
+		return fmt.Errorf("failed to marshal credential_process response: %w", err)
+	}
+	fmt.Println(string(encoded))
+	return nil
+}
+
+// credentialBroker refreshes the target-account credentials ahead of
+// Expires, with jitter, caching the result so repeated --serve requests
+// don't re-hit the OCM token exchange and backplane API on every renewal.
+// The seed context (OCM token exchange + initial STS assumption) and the
+// resolved role ARN sequence (backplane API lookup) are cached on first use
+// and reused across refreshes; only the STS calls that walk the chain are
+// repeated.
+type credentialBroker struct {
+	clusterArg string
+	debugFile  string
+
+	mu       sync.Mutex
+	seed     *seedContext
+	roleArns []string
+	cached   assumedCredentials
+}
+
+// refreshBefore is how far ahead of expiry a cached credential is considered
+// stale, with up to an extra minute of jitter to avoid every broker in a
+// fleet refreshing in lockstep.
+const refreshBefore = 5 * time.Minute
+
+// get returns the cached credentials, refreshing them first if they're
+// missing or within refreshBefore of expiry. Guarded by mu so concurrent
+// --serve requests can't race on the cached state or double-trigger the
+// assume chain.
+func (b *credentialBroker) get() (assumedCredentials, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.cached.AccessKeyID != "" && time.Until(b.cached.Expires) > refreshBefore+jitter() {
+		return b.cached, nil
+	}
+
+	if b.seed == nil {
+		seed, err := newSeedContext()
+		if err != nil {
+			return assumedCredentials{}, err
+		}
+
+		roleArns, _, err := seed.resolveRoleSequence(b.clusterArg, b.debugFile)
+		if err != nil {
+			return assumedCredentials{}, err
+		}
+
+		b.seed = &seed
+		b.roleArns = roleArns
+	}
+
+	creds, err := b.seed.walkRoleSequence(b.roleArns)
+	if err != nil {
+		return assumedCredentials{}, err
+	}
+	b.cached = creds
+	return creds, nil
+}
+
+func jitter() time.Duration {
+	return time.Duration(mathrand.Int63n(int64(time.Minute)))
+}
+
+// generateToken returns a random 256-bit bearer token, hex-encoded.
+func generateToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// serveCredentialProcess runs a loopback-only HTTP endpoint that mirrors the
+// IMDSv2/ECS container credentials JSON shape, refreshing the underlying
+// assume-role chain credentials on demand.
+func serveCredentialProcess(clusterArg string) error {
+	token := credentialProcessArgs.token
+	if token == "" {
+		generated, err := generateToken()
+		if err != nil {
+			return err
+		}
+		token = generated
+		fmt.Fprintf(noteOutput(), "Generated bearer token: %s\nSet AWS_CONTAINER_AUTHORIZATION_TOKEN to \"Bearer %s\".\n", token, token)
+	}
+
+	broker := &credentialBroker{clusterArg: clusterArg, debugFile: credentialProcessArgs.debugFile}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+token {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		creds, err := broker.get()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(toContainerCredentialsResponse(creds)); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+
+	listener, err := net.Listen("tcp", credentialProcessArgs.bindAddr)
+	if err != nil {
+		return fmt.Errorf("failed to bind --bind %q: %w", credentialProcessArgs.bindAddr, err)
+	}
+	if tcpAddr, ok := listener.Addr().(*net.TCPAddr); !ok || !tcpAddr.IP.IsLoopback() {
+		_ = listener.Close()
+		return fmt.Errorf("--bind %q is not a loopback address", credentialProcessArgs.bindAddr)
+	}
+
+	server := &http.Server{Handler: mux}
+	fmt.Fprintf(noteOutput(), "Serving rotating credentials on http://%s (Ctrl-C to stop)\nSet AWS_CONTAINER_CREDENTIALS_FULL_URI to http://%s\n", listener.Addr(), listener.Addr())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+
+	if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("credential-process server exited: %w", err)
+	}
+	return nil
+}
+
+// noteOutput is where operational notices (bind address, generated token) are
+// printed, kept separate from stdout since credential-process's stdout is
+// reserved for the credential_process JSON document.
+func noteOutput() io.Writer {
+	return os.Stderr
+}
@@ -0,0 +1,81 @@
+package cloud
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+const (
+	execCredentialAPIVersion = "client.authentication.k8s.io/v1"
+	clusterIDHeader          = "x-k8s-aws-id"
+	clusterTokenPrefix       = "k8s-aws-v1."
+)
+
+// execCredential is the subset of the client.authentication.k8s.io/v1
+// ExecCredential object (https://kubernetes.io/docs/reference/config-api/client-authentication.v1/)
+// that "backplane cloud assume -o exec-credential" needs to produce, for use
+// as a kubeconfig "exec" credential plugin.
+type execCredential struct {
+	Kind       string               `json:"kind"`
+	APIVersion string               `json:"apiVersion"`
+	Status     execCredentialStatus `json:"status"`
+}
+
+type execCredentialStatus struct {
+	ExpirationTimestamp string `json:"expirationTimestamp,omitempty"`
+	Token               string `json:"token"`
+}
+
+// newExecCredential renders targetCredentials as an ExecCredential whose
+// token is a presigned "sts:GetCallerIdentity" URL tagged with the target
+// EKS cluster's name and region, following the same scheme
+// aws-iam-authenticator/"aws eks get-token" use so kubectl/argocd can drive
+// the backplane jump-role chain as an exec credential plugin. eksClusterName
+// must be the EKS cluster's actual name, not the backplane cluster
+// ID/name/search term used to resolve the jump role chain: EKS IAM
+// authentication validates the token against the x-k8s-aws-id header and the
+// signing region, so a token tagged with anything else won't authenticate.
+func newExecCredential(ctx context.Context, eksClusterName, eksRegion string, targetCredentials assumedCredentials) (execCredential, error) {
+	token, err := presignClusterToken(ctx, eksClusterName, eksRegion, targetCredentials)
+	if err != nil {
+		return execCredential{}, err
+	}
+
+	return execCredential{
+		Kind:       "ExecCredential",
+		APIVersion: execCredentialAPIVersion,
+		Status: execCredentialStatus{
+			ExpirationTimestamp: targetCredentials.Expires.Format(time.RFC3339),
+			Token:               token,
+		},
+	}, nil
+}
+
+// presignClusterToken builds a "k8s-aws-v1."-prefixed, base64url-encoded
+// presigned sts:GetCallerIdentity URL carrying eksClusterName in the
+// x-k8s-aws-id header and signed for eksRegion, the token format the
+// Kubernetes AWS IAM authenticator expects from an exec credential plugin.
+func presignClusterToken(ctx context.Context, eksClusterName, eksRegion string, targetCredentials assumedCredentials) (string, error) {
+	stsClient := sts.NewFromConfig(aws.Config{
+		Region:      eksRegion,
+		Credentials: NewStaticCredentialsProvider(targetCredentials.AccessKeyID, targetCredentials.SecretAccessKey, targetCredentials.SessionToken),
+	})
+
+	presignClient := sts.NewPresignClient(stsClient)
+	presigned, err := presignClient.PresignGetCallerIdentity(ctx, &sts.GetCallerIdentityInput{}, func(po *sts.PresignOptions) {
+		po.ClientOptions = append(po.ClientOptions, func(o *sts.Options) {
+			o.APIOptions = append(o.APIOptions, smithyhttp.AddHeaderValue(clusterIDHeader, eksClusterName))
+		})
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to presign cluster token: %w", err)
+	}
+
+	return clusterTokenPrefix + base64.RawURLEncoding.EncodeToString([]byte(presigned.URL)), nil
+}
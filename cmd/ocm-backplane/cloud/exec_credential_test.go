@@ -0,0 +1,37 @@
+package cloud
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPresignClusterTokenEncoding(t *testing.T) {
+	creds := assumedCredentials{
+		AccessKeyID:     "AKIAEXAMPLE",
+		SecretAccessKey: "secret",
+		SessionToken:    "token",
+		Expires:         time.Now().Add(time.Hour),
+	}
+
+	token, err := presignClusterToken(context.Background(), "my-cluster", "us-west-2", creds)
+	if err != nil {
+		t.Fatalf("presignClusterToken: %v", err)
+	}
+
+	if !strings.HasPrefix(token, clusterTokenPrefix) {
+		t.Fatalf("token %q missing prefix %q", token, clusterTokenPrefix)
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(token, clusterTokenPrefix))
+	if err != nil {
+		t.Fatalf("token payload is not valid base64url: %v", err)
+	}
+
+	presignedURL := string(decoded)
+	if !strings.Contains(presignedURL, "us-west-2") {
+		t.Errorf("presigned URL %q does not reference the requested region", presignedURL)
+	}
+}
@@ -0,0 +1,79 @@
+package awsutil
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// AssumeRoleResponse is the trimmed-down set of fields pulled out of an
+// sts:AssumeRole(WithWebIdentity) response that gets passed on to the next
+// hop in a jump role chain, or returned to the caller as the final
+// target-account credentials.
+type AssumeRoleResponse struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Expires         time.Time
+}
+
+// STSClientProviderFunc builds the base, proxy-aware STS client used to
+// assume the next role in a jump role chain, with sourceIdentity's
+// confused-deputy headers attached to every request it makes.
+type STSClientProviderFunc func(proxyURL string, sourceIdentity SourceIdentity) (*sts.Client, error)
+
+// DefaultSTSClientProviderFunc is the production STSClientProviderFunc used
+// by AssumeRoleSequence to build each hop's client.
+func DefaultSTSClientProviderFunc(proxyURL string, sourceIdentity SourceIdentity) (*sts.Client, error) {
+	client, err := StsClientWithProxy(proxyURL)
+	if err != nil {
+		return nil, err
+	}
+	return sts.New(client.Options(), WithSourceIdentityHeaders(sourceIdentity)), nil
+}
+
+// AssumeRoleSequence walks roleArns in order, starting from seedClient, using
+// each hop's assumed credentials to build the client that assumes the next
+// role in the chain via clientProvider. sourceIdentity's
+// aws:SourceAccount/aws:SourceArn confused-deputy headers are attached to
+// every hop's client that clientProvider builds, so customers can lock down
+// a jump role's trust policy to only backplane's initial role regardless of
+// how many hops away from the seed assumption a given call is.
+func AssumeRoleSequence(sessionName string, seedClient *sts.Client, roleArns []string, proxyURL string, clientProvider STSClientProviderFunc, sourceIdentity SourceIdentity) (AssumeRoleResponse, error) {
+	client := seedClient
+	var result AssumeRoleResponse
+
+	for i, roleArn := range roleArns {
+		out, err := client.AssumeRole(context.TODO(), &sts.AssumeRoleInput{
+			RoleArn:         aws.String(roleArn),
+			RoleSessionName: aws.String(sessionName),
+		})
+		if err != nil {
+			return AssumeRoleResponse{}, err
+		}
+
+		result = AssumeRoleResponse{
+			AccessKeyID:     aws.ToString(out.Credentials.AccessKeyId),
+			SecretAccessKey: aws.ToString(out.Credentials.SecretAccessKey),
+			SessionToken:    aws.ToString(out.Credentials.SessionToken),
+			Expires:         aws.ToTime(out.Credentials.Expiration),
+		}
+
+		if i == len(roleArns)-1 {
+			break
+		}
+
+		nextClient, err := clientProvider(proxyURL, sourceIdentity)
+		if err != nil {
+			return AssumeRoleResponse{}, err
+		}
+		client = sts.New(nextClient.Options(), func(o *sts.Options) {
+			o.Credentials = credentials.NewStaticCredentialsProvider(result.AccessKeyID, result.SecretAccessKey, result.SessionToken)
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,216 @@
+package awsutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// ProfileCredentials holds the values written to a named profile's section in
+// the shared AWS credentials file.
+type ProfileCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// ProfileConfig holds the values written to a named profile's section in the
+// shared AWS config file.
+type ProfileConfig struct {
+	Region string
+	Output string
+}
+
+// UpsertCredentialsProfile atomically writes accessKeyID/secretAccessKey/sessionToken
+// into the `[profile]` section of the AWS shared credentials file at path,
+// creating the section if it does not already exist and leaving every other
+// section untouched. Concurrent callers are serialized with a file lock so
+// that two racing `backplane cloud assume` invocations cannot interleave writes.
+func UpsertCredentialsProfile(path, profile string, creds ProfileCredentials) error {
+	entries := []iniEntry{
+		{key: "aws_access_key_id", value: creds.AccessKeyID},
+		{key: "aws_secret_access_key", value: creds.SecretAccessKey},
+	}
+	if creds.SessionToken != "" {
+		entries = append(entries, iniEntry{key: "aws_session_token", value: creds.SessionToken})
+	}
+	return upsertIniSection(path, profile, entries)
+}
+
+// UpsertConfigProfile atomically writes region/output into the `[profile
+// <name>]` section (`[default]` for the "default" profile) of the AWS shared
+// config file at path, creating the
+// section if it does not already exist and leaving every other section
+// untouched.
+func UpsertConfigProfile(path, profile string, cfg ProfileConfig) error {
+	section := profile
+	if profile != "default" {
+		section = "profile " + profile
+	}
+
+	var entries []iniEntry
+	if cfg.Region != "" {
+		entries = append(entries, iniEntry{key: "region", value: cfg.Region})
+	}
+	if cfg.Output != "" {
+		entries = append(entries, iniEntry{key: "output", value: cfg.Output})
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	return upsertIniSection(path, section, entries)
+}
+
+type iniEntry struct {
+	key   string
+	value string
+}
+
+// upsertIniSection replaces the body of `[section]` in the INI file at path
+// with entries, preserving every other section (including blank lines and
+// comments) verbatim. The section is appended if it is not already present.
+// The file is created, along with its parent directory, if it does not exist.
+func upsertIniSection(path, section string, entries []iniEntry) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+
+	lock, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open lock file for %s: %w", path, err)
+	}
+	defer lock.Close()
+
+	if err := syscall.Flock(int(lock.Fd()), syscall.LOCK_EX); err != nil {
+		return fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+	defer syscall.Flock(int(lock.Fd()), syscall.LOCK_UN)
+
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	updated := replaceIniSection(string(existing), section, entries)
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-"+filepath.Base(path))
+	if err != nil {
+		return fmt.Errorf("failed to create temp file for %s: %w", path, err)
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName)
+
+	if _, err := tmp.WriteString(updated); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to chmod %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temp file for %s: %w", path, err)
+	}
+
+	if err := os.Rename(tmpName, path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
+
+// replaceIniSection returns content with the body of `[section]` replaced by
+// entries, appending a new section at the end of the file when it isn't found.
+func replaceIniSection(content, section string, entries []iniEntry) string {
+	header := "[" + section + "]"
+	var body strings.Builder
+	for _, e := range entries {
+		fmt.Fprintf(&body, "%s = %s\n", e.key, e.value)
+	}
+
+	lines := strings.Split(content, "\n")
+	out := make([]string, 0, len(lines)+len(entries)+1)
+
+	found := false
+	i := 0
+	for i < len(lines) {
+		line := lines[i]
+		if strings.TrimSpace(line) == header {
+			found = true
+			out = append(out, line)
+			out = append(out, strings.TrimRight(body.String(), "\n"))
+			i++
+			bodyStart := i
+			for i < len(lines) && !isIniSectionHeader(lines[i]) {
+				i++
+			}
+			// The blank lines immediately preceding the next section (or EOF)
+			// are a separator, not part of this section's body; preserve them
+			// so repeated upserts don't jam sections together.
+			trailingBlank := 0
+			for j := i - 1; j >= bodyStart && strings.TrimSpace(lines[j]) == ""; j-- {
+				trailingBlank++
+			}
+			for k := 0; k < trailingBlank; k++ {
+				out = append(out, "")
+			}
+			continue
+		}
+		out = append(out, line)
+		i++
+	}
+
+	result := strings.Join(out, "\n")
+	if !found {
+		if strings.TrimSpace(result) != "" && !strings.HasSuffix(result, "\n") {
+			result += "\n"
+		}
+		if strings.TrimSpace(result) != "" {
+			result += "\n"
+		}
+		result += header + "\n" + body.String()
+	}
+	return ensureTrailingNewline(result)
+}
+
+func isIniSectionHeader(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]")
+}
+
+func ensureTrailingNewline(s string) string {
+	if s == "" {
+		return s
+	}
+	if !strings.HasSuffix(s, "\n") {
+		return s + "\n"
+	}
+	return s
+}
+
+// DefaultCredentialsFilePath returns the location of the shared AWS
+// credentials file, honoring AWS_SHARED_CREDENTIALS_FILE like the AWS CLI does.
+func DefaultCredentialsFilePath() (string, error) {
+	if f := os.Getenv("AWS_SHARED_CREDENTIALS_FILE"); f != "" {
+		return f, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine home directory: %w", err)
+	}
+	return filepath.Join(home, ".aws", "credentials"), nil
+}
+
+// DefaultConfigFilePath returns the location of the shared AWS config file,
+// honoring AWS_CONFIG_FILE like the AWS CLI does.
+func DefaultConfigFilePath() (string, error) {
+	if f := os.Getenv("AWS_CONFIG_FILE"); f != "" {
+		return f, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine home directory: %w", err)
+	}
+	return filepath.Join(home, ".aws", "config"), nil
+}
@@ -0,0 +1,116 @@
+package awsutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUpsertCredentialsProfileCreatesMissingFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "nested", "credentials")
+
+	if err := UpsertCredentialsProfile(path, "sre-prod", ProfileCredentials{
+		AccessKeyID:     "AKIA",
+		SecretAccessKey: "secret",
+		SessionToken:    "token",
+	}); err != nil {
+		t.Fatalf("UpsertCredentialsProfile: %v", err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	want := "[sre-prod]\naws_access_key_id = AKIA\naws_secret_access_key = secret\naws_session_token = token\n"
+	if string(got) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestUpsertConfigProfileSectionNaming(t *testing.T) {
+	tests := []struct {
+		profile string
+		want    string
+	}{
+		{profile: "default", want: "[default]\nregion = us-east-1\noutput = json\n"},
+		{profile: "sre-prod", want: "[profile sre-prod]\nregion = us-east-1\noutput = json\n"},
+	}
+
+	for _, tt := range tests {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "config")
+
+		if err := UpsertConfigProfile(path, tt.profile, ProfileConfig{Region: "us-east-1", Output: "json"}); err != nil {
+			t.Fatalf("UpsertConfigProfile(%q): %v", tt.profile, err)
+		}
+
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("ReadFile: %v", err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("profile %q: got:\n%s\nwant:\n%s", tt.profile, got, tt.want)
+		}
+	}
+}
+
+func TestUpsertCredentialsProfilePreservesOtherSections(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "credentials")
+
+	initial := "[default]\naws_access_key_id = DEFAULT\naws_secret_access_key = defaultsecret\n\n[other]\naws_access_key_id = OTHER\naws_secret_access_key = othersecret\n"
+	if err := os.WriteFile(path, []byte(initial), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	if err := UpsertCredentialsProfile(path, "default", ProfileCredentials{
+		AccessKeyID:     "NEW",
+		SecretAccessKey: "newsecret",
+	}); err != nil {
+		t.Fatalf("UpsertCredentialsProfile: %v", err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	want := "[default]\naws_access_key_id = NEW\naws_secret_access_key = newsecret\n\n[other]\naws_access_key_id = OTHER\naws_secret_access_key = othersecret\n"
+	if string(got) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// TestUpsertCredentialsProfileRoundTrip guards against the sections jamming
+// together (losing the blank line separator) that a repeated upsert used to
+// cause.
+func TestUpsertCredentialsProfileRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "credentials")
+
+	initial := "[default]\naws_access_key_id = DEFAULT\n\n[other]\naws_access_key_id = OTHER\n"
+	if err := os.WriteFile(path, []byte(initial), 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := UpsertCredentialsProfile(path, "default", ProfileCredentials{
+			AccessKeyID:     "NEW",
+			SecretAccessKey: "newsecret",
+		}); err != nil {
+			t.Fatalf("UpsertCredentialsProfile (iteration %d): %v", i, err)
+		}
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+
+	want := "[default]\naws_access_key_id = NEW\naws_secret_access_key = newsecret\n\n[other]\naws_access_key_id = OTHER\n"
+	if string(got) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,53 @@
+package awsutil
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// SourceIdentity carries the confused-deputy protection values that get
+// attached, as x-amz-source-account / x-amz-source-arn headers, to every
+// sts:AssumeRole and sts:AssumeRoleWithWebIdentity call made while walking
+// the jump role chain. Customers can add aws:SourceArn/aws:SourceAccount
+// conditions to their trust policies to ensure only backplane's initial role
+// can assume into their account.
+type SourceIdentity struct {
+	SourceAccountID string
+	SourceArn       string
+}
+
+// WithSourceIdentityHeaders returns an sts.Options functional option that
+// registers a build middleware setting the source identity headers, for use
+// with sts.New/sts.NewFromConfig so the same identity is sent consistently
+// across the seed client and every per-hop client in the chain.
+func WithSourceIdentityHeaders(identity SourceIdentity) func(*sts.Options) {
+	return func(o *sts.Options) {
+		if identity.SourceAccountID == "" && identity.SourceArn == "" {
+			return
+		}
+		o.APIOptions = append(o.APIOptions, func(stack *middleware.Stack) error {
+			return stack.Build.Add(sourceIdentityMiddleware(identity), middleware.After)
+		})
+	}
+}
+
+func sourceIdentityMiddleware(identity SourceIdentity) middleware.BuildMiddleware {
+	return middleware.BuildMiddlewareFunc("SourceIdentityHeaders", func(
+		ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler,
+	) (middleware.BuildOutput, middleware.Metadata, error) {
+		req, ok := in.Request.(*smithyhttp.Request)
+		if !ok {
+			return next.HandleBuild(ctx, in)
+		}
+		if identity.SourceAccountID != "" {
+			req.Header.Set("x-amz-source-account", identity.SourceAccountID)
+		}
+		if identity.SourceArn != "" {
+			req.Header.Set("x-amz-source-arn", identity.SourceArn)
+		}
+		return next.HandleBuild(ctx, in)
+	})
+}